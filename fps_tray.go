@@ -1,56 +1,17 @@
-//go:build windows
-// +build windows
-
 package main
 
 import (
-	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
 	"runtime"
-	"strconv"
 	"strings"
-	"syscall"
+	"sync/atomic"
 	"time"
-	"unsafe"
-
-	"github.com/getlantern/systray"
-)
-
-const (
-	FILE_MAP_READ      = 0x0004
-	MAHM_SHARED_MEMORY = "MAHMSharedMemory"
 )
 
-// structures based on MAHM v2.0 spec
-type SharedHeader struct {
-	Signature     uint32
-	Version       uint32
-	HeaderSize    uint32
-	NumEntries    uint32
-	EntrySize     uint32
-	Time          int64
-	NumGpuEntries uint32
-	GpuEntrySize  uint32
-}
-
-type Entry struct {
-	SrcName   [260]byte
-	SrcUnits  [260]byte
-	LocalName [260]byte
-	LocalUnit [260]byte
-	Format    [260]byte
-	Data      float32
-	Min, Max  float32
-	Flags     uint32
-	GpuIndex  uint32
-	SrcId     uint32
-	_pad      uint32 // alignment padding
-}
-
 // API Response structures
 type SensorData struct {
 	Name      string   `json:"name"`
@@ -61,18 +22,18 @@ type SensorData struct {
 	Timestamp int64    `json:"timestamp"`
 }
 
+// SystemStatus holds one collector snapshot. The per-category slices start
+// out nil and grow via append, so games that expose more than the old
+// 100-entry cap (multi-GPU rigs, per-core CPU frequencies) no longer get
+// truncated.
 type SystemStatus struct {
-	Timestamp   int64           `json:"timestamp"`
-	FPS         [100]SensorData `json:"fps"`
-	GPU         [100]SensorData `json:"gpu"`
-	CPU         [100]SensorData `json:"cpu"`
-	Memory      [100]SensorData `json:"memory"`
-	All         [100]SensorData `json:"all"`
-	FPSCount    int             `json:"fps_count"`
-	GPUCount    int             `json:"gpu_count"`
-	CPUCount    int             `json:"cpu_count"`
-	MemoryCount int             `json:"memory_count"`
-	AllCount    int             `json:"all_count"`
+	Timestamp int64         `json:"timestamp"`
+	FPS       []SensorData  `json:"fps"`
+	GPU       []SensorData  `json:"gpu"`
+	CPU       []SensorData  `json:"cpu"`
+	Memory    []SensorData  `json:"memory"`
+	All       []SensorData  `json:"all"`
+	Process   *ProcessStats `json:"process,omitempty"`
 }
 
 type MemInfo struct {
@@ -85,20 +46,37 @@ type MemInfo struct {
 }
 
 type FPSApp struct {
-	stop       chan bool
-	port       string
-	latestData SystemStatus
-	dataMutex  chan struct{}
+	stop        chan bool
+	port        string
+	latest      atomic.Pointer[SystemStatus]
+	sources     []SensorSource
+	broadcaster *broadcaster
+	mqtt        *MQTTPublisher
 }
 
 func main() {
-	// Kill any existing fps_tray.exe process
+	var mqttCfg MQTTConfig
+	flag.StringVar(&gameProcessName, "game-process", "", "process name to enrich /cpu, /memory and /process with per-process stats")
+	flag.StringVar(&mqttCfg.Broker, "mqtt-broker", "", "MQTT broker URL (e.g. tcp://localhost:1883) for HomeAssistant auto-discovery; leave empty to disable")
+	flag.StringVar(&mqttCfg.Username, "mqtt-username", "", "MQTT username")
+	flag.StringVar(&mqttCfg.Password, "mqtt-password", "", "MQTT password")
+	flag.StringVar(&mqttCfg.ClientID, "mqtt-client-id", "fps_monitor", "MQTT client ID")
+	flag.BoolVar(&mqttCfg.TLS, "mqtt-tls", false, "use TLS when connecting to the MQTT broker")
+	flag.Parse()
+
+	// Kill any existing fps_tray process
 	killExistingProcess()
 
 	app := &FPSApp{
-		stop:      make(chan bool),
-		port:      "8080",
-		dataMutex: make(chan struct{}, 1),
+		stop:        make(chan bool),
+		port:        "8080",
+		sources:     defaultSensorSources(),
+		broadcaster: newBroadcaster(),
+	}
+
+	if mqttCfg.Broker != "" {
+		app.mqtt = NewMQTTPublisher(mqttCfg)
+		defer app.mqtt.Close()
 	}
 
 	// Start the data collection goroutine
@@ -108,25 +86,25 @@ func main() {
 	go app.startServer()
 
 	// Run the system tray
-	systray.Run(app.onReady, app.onExit)
+	trayRun(app.onReady, app.onExit)
 }
 
 func (app *FPSApp) onReady() {
 	// Set custom icon
-	systray.SetIcon(getIcon())
-	systray.SetTitle("FPS Monitor")
-	systray.SetTooltip("FPS Monitoring Server")
+	traySetIcon(getIcon())
+	traySetTitle("FPS Monitor")
+	traySetTooltip("FPS Monitoring Server")
 
 	// Add menu items
-	systray.AddSeparator()
-	mQuit := systray.AddMenuItem("Quit", "Quit the application")
+	trayAddSeparator()
+	mQuit := trayAddMenuItem("Quit", "Quit the application")
 
 	// Handle menu events
 	go func() {
 		for {
 			select {
 			case <-mQuit.ClickedCh:
-				systray.Quit()
+				trayQuit()
 				return
 			case <-app.stop:
 				return
@@ -140,42 +118,6 @@ func (app *FPSApp) onExit() {
 	close(app.stop)
 }
 
-func killExistingProcess() {
-	// Get current process ID
-	currentPID := os.Getpid()
-
-	// Use tasklist to find fps_tray.exe processes
-	cmd := exec.Command("tasklist", "/FI", "IMAGENAME eq fps_tray.exe", "/FO", "CSV", "/NH")
-	output, err := cmd.Output()
-	if err != nil {
-		return // Ignore errors, just continue
-	}
-
-	// Parse the output to find PIDs
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "fps_tray.exe") {
-			// Extract PID from CSV format: "fps_tray.exe","1234","Console","1","1,234 K"
-			parts := strings.Split(line, ",")
-			if len(parts) >= 2 {
-				pidStr := strings.Trim(parts[1], "\"")
-				if pid, err := strconv.Atoi(pidStr); err == nil {
-					// Don't kill ourselves
-					if pid != currentPID {
-						fmt.Printf("Killing existing fps_tray.exe process (PID: %d)\n", pid)
-						// Use taskkill to terminate the process
-						killCmd := exec.Command("taskkill", "/PID", strconv.Itoa(pid), "/F")
-						killCmd.Run() // Ignore errors
-					}
-				}
-			}
-		}
-	}
-
-	// Give a moment for the process to terminate
-	time.Sleep(100 * time.Millisecond)
-}
-
 func getIcon() []byte {
 	// Read the icon file
 	iconData, err := os.ReadFile("FPSserver.ico")
@@ -186,6 +128,13 @@ func getIcon() []byte {
 	return iconData
 }
 
+// collectData polls the configured sensor sources once a second, trying
+// each in order and keeping the first one that reports data. This lets
+// MAHM take priority when MSI Afterburner is running, with gopsutil
+// picking up the slack otherwise. Each tick builds a brand new
+// SystemStatus off to the side and publishes it with a single atomic
+// Store, so handlers never block behind the collector and never see a
+// half-written snapshot.
 func (app *FPSApp) collectData() {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -193,163 +142,38 @@ func (app *FPSApp) collectData() {
 	for {
 		select {
 		case <-ticker.C:
-			// Read data directly into the existing structure
-			select {
-			case app.dataMutex <- struct{}{}:
-				app.readMSIDataInto(&app.latestData)
-				app.latestData.Timestamp = time.Now().Unix()
-				<-app.dataMutex
-			default:
-				// Skip if mutex is busy
+			status := &SystemStatus{Timestamp: time.Now().Unix()}
+			for _, src := range app.sources {
+				if src.Collect(status) {
+					break
+				}
 			}
-		case <-app.stop:
-			return
-		}
-	}
-}
+			enrichProcessStats(status, gameProcessName)
 
-func (app *FPSApp) readMSIDataInto(status *SystemStatus) {
-	// Use a static buffer to avoid allocations
-	nameBytes := []byte(MAHM_SHARED_MEMORY + "\x00")
-
-	// Load DLL functions
-	kernel32 := syscall.NewLazyDLL("kernel32.dll")
-	openFileMapping := kernel32.NewProc("OpenFileMappingA")
-	closeHandle := kernel32.NewProc("CloseHandle")
-	mapViewOfFile := kernel32.NewProc("MapViewOfFile")
-	unmapViewOfFile := kernel32.NewProc("UnmapViewOfFile")
-
-	handle, _, _ := openFileMapping.Call(uintptr(FILE_MAP_READ), 0, uintptr(unsafe.Pointer(&nameBytes[0])))
-	if handle == 0 {
-		// Reset counters
-		status.FPSCount = 0
-		status.GPUCount = 0
-		status.CPUCount = 0
-		status.MemoryCount = 0
-		status.AllCount = 0
-		return
-	}
-	defer closeHandle.Call(handle)
-
-	view, _, _ := mapViewOfFile.Call(handle, uintptr(FILE_MAP_READ), 0, 0, 0)
-	if view == 0 {
-		// Reset counters
-		status.FPSCount = 0
-		status.GPUCount = 0
-		status.CPUCount = 0
-		status.MemoryCount = 0
-		status.AllCount = 0
-		return
-	}
-	defer unmapViewOfFile.Call(view)
-
-	hdr := (*SharedHeader)(unsafe.Pointer(view))
-	if hdr == nil || hdr.Signature != 0x4D41484D { // 'MAHM' in little-endian
-		// Reset counters
-		status.FPSCount = 0
-		status.GPUCount = 0
-		status.CPUCount = 0
-		status.MemoryCount = 0
-		status.AllCount = 0
-		return
-	}
-
-	entriesBase := view + uintptr(hdr.HeaderSize)
-
-	// Reset counters
-	status.FPSCount = 0
-	status.GPUCount = 0
-	status.CPUCount = 0
-	status.MemoryCount = 0
-	status.AllCount = 0
-
-	for i := uint32(0); i < hdr.NumEntries; i++ {
-		off := uintptr(i) * uintptr(hdr.EntrySize)
-		e := (*Entry)(unsafe.Pointer(entriesBase + off))
-
-		// Validate entry pointer
-		if e == nil {
-			continue
-		}
-
-		// Find null terminators safely
-		nameEnd := bytes.IndexByte(e.SrcName[:], 0)
-		if nameEnd == -1 {
-			nameEnd = len(e.SrcName)
-		}
-		unitEnd := bytes.IndexByte(e.SrcUnits[:], 0)
-		if unitEnd == -1 {
-			unitEnd = len(e.SrcUnits)
-		}
-
-		// Convert to strings
-		nameStr := string(e.SrcName[:nameEnd])
-		unitStr := string(e.SrcUnits[:unitEnd])
-
-		// Handle invalid values for HomeAssistant compatibility
-		var sensorValue *float32
-		if e.Data >= 3.4e+38 || e.Data <= -3.4e+38 {
-			sensorValue = nil // Use null for invalid values
-		} else {
-			// Create a copy of the data to avoid memory issues
-			dataCopy := e.Data
-			sensorValue = &dataCopy
-		}
-
-		sensor := SensorData{
-			Name:      nameStr,
-			Value:     sensorValue,
-			Unit:      unitStr,
-			GpuIndex:  e.GpuIndex,
-			Category:  app.categorizeSensor(nameStr),
-			Timestamp: status.Timestamp,
-		}
-
-		// Add to All array if space available
-		if status.AllCount < 100 {
-			status.All[status.AllCount] = sensor
-			status.AllCount++
-		}
-
-		// Categorize sensors
-		switch sensor.Category {
-		case "fps":
-			if status.FPSCount < 100 {
-				status.FPS[status.FPSCount] = sensor
-				status.FPSCount++
-			}
-		case "gpu":
-			if status.GPUCount < 100 {
-				status.GPU[status.GPUCount] = sensor
-				status.GPUCount++
-			}
-		case "cpu":
-			if status.CPUCount < 100 {
-				status.CPU[status.CPUCount] = sensor
-				status.CPUCount++
-			}
-		case "memory":
-			if status.MemoryCount < 100 {
-				status.Memory[status.MemoryCount] = sensor
-				status.MemoryCount++
+			app.latest.Store(status)
+			app.broadcaster.publish(status)
+			if app.mqtt != nil {
+				app.mqtt.Publish(status)
 			}
+		case <-app.stop:
+			return
 		}
 	}
 }
 
-func (app *FPSApp) categorizeSensor(name string) string {
-	nameLower := bytes.ToLower([]byte(name))
+func categorizeSensor(name string) string {
+	nameLower := strings.ToLower(name)
 
-	if bytes.Contains(nameLower, []byte("fps")) || bytes.Contains(nameLower, []byte("framerate")) || bytes.Contains(nameLower, []byte("frametime")) {
+	if strings.Contains(nameLower, "fps") || strings.Contains(nameLower, "framerate") || strings.Contains(nameLower, "frametime") {
 		return "fps"
 	}
-	if bytes.Contains(nameLower, []byte("gpu")) {
+	if strings.Contains(nameLower, "gpu") {
 		return "gpu"
 	}
-	if bytes.Contains(nameLower, []byte("cpu")) {
+	if strings.Contains(nameLower, "cpu") {
 		return "cpu"
 	}
-	if bytes.Contains(nameLower, []byte("memory")) || bytes.Contains(nameLower, []byte("ram")) {
+	if strings.Contains(nameLower, "memory") || strings.Contains(nameLower, "ram") {
 		return "memory"
 	}
 	return "other"
@@ -361,7 +185,11 @@ func (app *FPSApp) startServer() {
 	http.HandleFunc("/fps", app.handleFPS)
 	http.HandleFunc("/gpu", app.handleGPU)
 	http.HandleFunc("/memory", app.handleMemory)
+	http.HandleFunc("/process", app.handleProcess)
 	http.HandleFunc("/debug/memory", app.handleMemoryStats)
+	http.HandleFunc("/metrics", app.handleMetrics)
+	http.HandleFunc("/stream/ws", app.handleStreamWS)
+	http.HandleFunc("/stream/sse", app.handleStreamSSE)
 	http.HandleFunc("/", app.handleRoot)
 
 	_ = http.ListenAndServe("0.0.0.0:"+app.port, nil)
@@ -372,10 +200,14 @@ var rootResponse = map[string]interface{}{
 	"service": "FPS Monitor",
 	"version": "1.0.0",
 	"endpoints": map[string]string{
-		"cpu":    "/cpu",
-		"fps":    "/fps",
-		"gpu":    "/gpu",
-		"memory": "/memory",
+		"cpu":        "/cpu",
+		"fps":        "/fps",
+		"gpu":        "/gpu",
+		"memory":     "/memory",
+		"process":    "/process",
+		"metrics":    "/metrics",
+		"stream_ws":  "/stream/ws",
+		"stream_sse": "/stream/sse",
 	},
 }
 
@@ -389,52 +221,101 @@ func (app *FPSApp) handleFPS(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	select {
-	case app.dataMutex <- struct{}{}:
-		defer func() { <-app.dataMutex }()
-		json.NewEncoder(w).Encode(app.latestData.FPS[:app.latestData.FPSCount])
-	default:
+	status := app.latest.Load()
+	if status == nil {
 		http.Error(w, "Data not available", http.StatusServiceUnavailable)
+		return
 	}
+	json.NewEncoder(w).Encode(status.FPS)
 }
 
 func (app *FPSApp) handleGPU(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	select {
-	case app.dataMutex <- struct{}{}:
-		defer func() { <-app.dataMutex }()
-		json.NewEncoder(w).Encode(app.latestData.GPU[:app.latestData.GPUCount])
-	default:
+	status := app.latest.Load()
+	if status == nil {
 		http.Error(w, "Data not available", http.StatusServiceUnavailable)
+		return
 	}
+	json.NewEncoder(w).Encode(status.GPU)
 }
 
 func (app *FPSApp) handleCPU(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	select {
-	case app.dataMutex <- struct{}{}:
-		defer func() { <-app.dataMutex }()
-		json.NewEncoder(w).Encode(app.latestData.CPU[:app.latestData.CPUCount])
-	default:
+	status := app.latest.Load()
+	if status == nil {
 		http.Error(w, "Data not available", http.StatusServiceUnavailable)
+		return
 	}
+	json.NewEncoder(w).Encode(status.CPU)
 }
 
 func (app *FPSApp) handleMemory(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	select {
-	case app.dataMutex <- struct{}{}:
-		defer func() { <-app.dataMutex }()
-		json.NewEncoder(w).Encode(app.latestData.Memory[:app.latestData.MemoryCount])
-	default:
+	status := app.latest.Load()
+	if status == nil {
 		http.Error(w, "Data not available", http.StatusServiceUnavailable)
+		return
 	}
+	json.NewEncoder(w).Encode(status.Memory)
+}
+
+func (app *FPSApp) handleProcess(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	status := app.latest.Load()
+	if status == nil || status.Process == nil {
+		http.Error(w, "Data not available", http.StatusServiceUnavailable)
+		return
+	}
+	json.NewEncoder(w).Encode(status.Process)
+}
+
+// handleMetrics exposes every sensor plus the Go runtime stats as
+// Prometheus text-format gauges, so the server can be scraped directly
+// instead of polled as JSON.
+func (app *FPSApp) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	status := app.latest.Load()
+	if status == nil {
+		http.Error(w, "Data not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("# HELP fps_monitor_sensor Current value of a monitored sensor.\n")
+	b.WriteString("# TYPE fps_monitor_sensor gauge\n")
+	for _, s := range status.All {
+		// Sensors carrying the MAHM "not available" sentinel (±3.4e+38) were
+		// already turned into a nil Value by the source that collected them;
+		// skip them here rather than emitting NaN, which most scrapers reject.
+		if s.Value == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "fps_monitor_sensor{name=%q,unit=%q,gpu_index=\"%d\",category=%q} %g\n",
+			s.Name, s.Unit, s.GpuIndex, s.Category, *s.Value)
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	writeGauge(&b, "fps_monitor_go_heap_alloc_bytes", "Go heap bytes allocated and in use.", float64(m.HeapAlloc))
+	writeGauge(&b, "fps_monitor_go_total_alloc_bytes", "Cumulative bytes allocated for heap objects.", float64(m.TotalAlloc))
+	writeGauge(&b, "fps_monitor_go_heap_inuse_bytes", "Bytes in in-use spans.", float64(m.HeapInuse))
+	writeGauge(&b, "fps_monitor_go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine()))
+	writeGauge(&b, "fps_monitor_go_gc_runs_total", "Number of completed GC cycles.", float64(m.NumGC))
+
+	w.Write([]byte(b.String()))
+}
+
+func writeGauge(b *strings.Builder, name, help string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
 }
 
 func (app *FPSApp) handleMemoryStats(w http.ResponseWriter, r *http.Request) {
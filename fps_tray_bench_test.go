@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// BenchmarkHandleFPSConcurrent drives handleFPS the way ~1000 dashboard
+// clients polling at once would. With reads served off a single
+// atomic.Pointer Load instead of the old dataMutex gate, none of them
+// should contend with each other or with the collector.
+func BenchmarkHandleFPSConcurrent(b *testing.B) {
+	app := &FPSApp{}
+	app.latest.Store(&SystemStatus{
+		Timestamp: 1,
+		FPS: []SensorData{
+			{Name: "Framerate", Unit: "FPS", Category: "fps"},
+		},
+	})
+
+	b.SetParallelism(1000)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		req := httptest.NewRequest(http.MethodGet, "/fps", nil)
+		for pb.Next() {
+			rec := httptest.NewRecorder()
+			app.handleFPS(rec, req)
+		}
+	})
+}
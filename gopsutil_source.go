@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// GopsutilSource collects host stats via gopsutil. It is used whenever the
+// MAHM shared memory segment isn't available — MSI Afterburner isn't
+// running, or the build isn't Windows at all — so the server still has
+// something useful to report on Linux/macOS gaming rigs such as a Steam
+// Deck.
+type GopsutilSource struct{}
+
+func (s *GopsutilSource) Collect(status *SystemStatus) bool {
+	found := false
+
+	if percents, err := cpu.Percent(0, true); err == nil {
+		for i, pct := range percents {
+			addSensor(status, "cpu", "cpu_core_usage", floatPtr(float32(pct)), "%", uint32(i))
+			found = true
+		}
+	}
+	if infos, err := cpu.Info(); err == nil {
+		for i, c := range infos {
+			addSensor(status, "cpu", "cpu_core_frequency", floatPtr(float32(c.Mhz)), "MHz", uint32(i))
+			found = true
+		}
+	}
+	if avg, err := load.Avg(); err == nil {
+		addSensor(status, "cpu", "load_average_1m", floatPtr(float32(avg.Load1)), "load", 0)
+		addSensor(status, "cpu", "load_average_5m", floatPtr(float32(avg.Load5)), "load", 0)
+		addSensor(status, "cpu", "load_average_15m", floatPtr(float32(avg.Load15)), "load", 0)
+		found = true
+	}
+
+	if vm, err := mem.VirtualMemory(); err == nil {
+		addSensor(status, "memory", "memory_used", floatPtr(float32(vm.Used)), "bytes", 0)
+		addSensor(status, "memory", "memory_available", floatPtr(float32(vm.Available)), "bytes", 0)
+		addSensor(status, "memory", "memory_used_percent", floatPtr(float32(vm.UsedPercent)), "%", 0)
+		found = true
+	}
+	if sm, err := mem.SwapMemory(); err == nil {
+		addSensor(status, "memory", "swap_used", floatPtr(float32(sm.Used)), "bytes", 0)
+		addSensor(status, "memory", "swap_used_percent", floatPtr(float32(sm.UsedPercent)), "%", 0)
+		found = true
+	}
+
+	if counters, err := disk.IOCounters(); err == nil {
+		for name, c := range counters {
+			addSensor(status, "disk", name+"_read_bytes", floatPtr(float32(c.ReadBytes)), "bytes", 0)
+			addSensor(status, "disk", name+"_write_bytes", floatPtr(float32(c.WriteBytes)), "bytes", 0)
+			found = true
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,162 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	FILE_MAP_READ      = 0x0004
+	MAHM_SHARED_MEMORY = "MAHMSharedMemory"
+)
+
+// structures based on MAHM v2.0 spec
+type SharedHeader struct {
+	Signature     uint32
+	Version       uint32
+	HeaderSize    uint32
+	NumEntries    uint32
+	EntrySize     uint32
+	Time          int64
+	NumGpuEntries uint32
+	GpuEntrySize  uint32
+}
+
+type Entry struct {
+	SrcName   [260]byte
+	SrcUnits  [260]byte
+	LocalName [260]byte
+	LocalUnit [260]byte
+	Format    [260]byte
+	Data      float32
+	Min, Max  float32
+	Flags     uint32
+	GpuIndex  uint32
+	SrcId     uint32
+	_pad      uint32 // alignment padding
+}
+
+// MAHMSource reads sensor data out of the MSI Afterburner (RivaTuner) MAHM
+// shared memory segment. It reports false when the segment can't be mapped
+// (MSI Afterburner isn't running), so collectData falls back to the next
+// configured source.
+type MAHMSource struct{}
+
+func (s *MAHMSource) Collect(status *SystemStatus) bool {
+	// Use a static buffer to avoid allocations
+	nameBytes := []byte(MAHM_SHARED_MEMORY + "\x00")
+
+	// Load DLL functions
+	kernel32 := syscall.NewLazyDLL("kernel32.dll")
+	openFileMapping := kernel32.NewProc("OpenFileMappingA")
+	closeHandle := kernel32.NewProc("CloseHandle")
+	mapViewOfFile := kernel32.NewProc("MapViewOfFile")
+	unmapViewOfFile := kernel32.NewProc("UnmapViewOfFile")
+
+	handle, _, _ := openFileMapping.Call(uintptr(FILE_MAP_READ), 0, uintptr(unsafe.Pointer(&nameBytes[0])))
+	if handle == 0 {
+		return false
+	}
+	defer closeHandle.Call(handle)
+
+	view, _, _ := mapViewOfFile.Call(handle, uintptr(FILE_MAP_READ), 0, 0, 0)
+	if view == 0 {
+		return false
+	}
+	defer unmapViewOfFile.Call(view)
+
+	hdr := (*SharedHeader)(unsafe.Pointer(view))
+	if hdr == nil || hdr.Signature != 0x4D41484D { // 'MAHM' in little-endian
+		return false
+	}
+
+	entriesBase := view + uintptr(hdr.HeaderSize)
+	found := false
+
+	for i := uint32(0); i < hdr.NumEntries; i++ {
+		off := uintptr(i) * uintptr(hdr.EntrySize)
+		e := (*Entry)(unsafe.Pointer(entriesBase + off))
+
+		// Validate entry pointer
+		if e == nil {
+			continue
+		}
+
+		// Find null terminators safely
+		nameEnd := bytes.IndexByte(e.SrcName[:], 0)
+		if nameEnd == -1 {
+			nameEnd = len(e.SrcName)
+		}
+		unitEnd := bytes.IndexByte(e.SrcUnits[:], 0)
+		if unitEnd == -1 {
+			unitEnd = len(e.SrcUnits)
+		}
+
+		// Convert to strings
+		nameStr := string(e.SrcName[:nameEnd])
+		unitStr := string(e.SrcUnits[:unitEnd])
+
+		// Handle invalid values for HomeAssistant compatibility
+		var sensorValue *float32
+		if e.Data < 3.4e+38 && e.Data > -3.4e+38 {
+			sensorValue = floatPtr(e.Data)
+		}
+
+		addSensor(status, categorizeSensor(nameStr), nameStr, sensorValue, unitStr, e.GpuIndex)
+		found = true
+	}
+
+	return found
+}
+
+func killExistingProcess() {
+	// Get current process ID
+	currentPID := os.Getpid()
+
+	// Use tasklist to find fps_tray.exe processes
+	cmd := exec.Command("tasklist", "/FI", "IMAGENAME eq fps_tray.exe", "/FO", "CSV", "/NH")
+	output, err := cmd.Output()
+	if err != nil {
+		return // Ignore errors, just continue
+	}
+
+	// Parse the output to find PIDs
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		if strings.Contains(line, "fps_tray.exe") {
+			// Extract PID from CSV format: "fps_tray.exe","1234","Console","1","1,234 K"
+			parts := strings.Split(line, ",")
+			if len(parts) >= 2 {
+				pidStr := strings.Trim(parts[1], "\"")
+				if pid, err := strconv.Atoi(pidStr); err == nil {
+					// Don't kill ourselves
+					if pid != currentPID {
+						fmt.Printf("Killing existing fps_tray.exe process (PID: %d)\n", pid)
+						// Use taskkill to terminate the process
+						killCmd := exec.Command("taskkill", "/PID", strconv.Itoa(pid), "/F")
+						killCmd.Run() // Ignore errors
+					}
+				}
+			}
+		}
+	}
+
+	// Give a moment for the process to terminate
+	time.Sleep(100 * time.Millisecond)
+}
+
+// defaultSensorSources prefers MAHM (MSI Afterburner) when available and
+// falls back to gopsutil otherwise, e.g. when Afterburner isn't running.
+func defaultSensorSources() []SensorSource {
+	return []SensorSource{&MAHMSource{}, &GopsutilSource{}}
+}
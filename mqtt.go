@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MQTTConfig holds the CLI-configurable connection details for the optional
+// HomeAssistant publisher.
+type MQTTConfig struct {
+	Broker   string
+	Username string
+	Password string
+	TLS      bool
+	ClientID string
+}
+
+// MQTTPublisher pushes every sensor in a SystemStatus to HomeAssistant's
+// MQTT discovery topics, so HomeAssistant can pick the server up with
+// push-based updates instead of the REST-sensor polling loop the JSON API
+// otherwise forces.
+type MQTTPublisher struct {
+	client    mqtt.Client
+	announced map[string]bool
+}
+
+// NewMQTTPublisher returns a publisher and connects to the configured
+// broker in the background. MQTT is an optional add-on per the feature
+// request, so a slow or unreachable broker must never delay startup of
+// the core /fps, /gpu, /cpu endpoints: Publish simply no-ops until the
+// connection comes up, and Paho keeps retrying on its own after that.
+func NewMQTTPublisher(cfg MQTTConfig) *MQTTPublisher {
+	opts := mqtt.NewClientOptions().AddBroker(cfg.Broker).SetClientID(cfg.ClientID)
+	if cfg.Username != "" {
+		opts.SetUsername(cfg.Username)
+		opts.SetPassword(cfg.Password)
+	}
+	if cfg.TLS {
+		opts.SetTLSConfig(&tls.Config{})
+	}
+	// Retained messages + a persistent session let HomeAssistant come back
+	// up with the last-known sensor values even across a server restart.
+	opts.SetCleanSession(false)
+	opts.SetConnectTimeout(10 * time.Second)
+	opts.SetAutoReconnect(true)
+	opts.SetConnectRetry(true)
+	opts.SetConnectRetryInterval(5 * time.Second)
+
+	client := mqtt.NewClient(opts)
+	publisher := &MQTTPublisher{client: client, announced: make(map[string]bool)}
+
+	go func() {
+		token := client.Connect()
+		token.Wait()
+		if err := token.Error(); err != nil {
+			fmt.Printf("mqtt: failed to connect to %s: %v\n", cfg.Broker, err)
+		}
+	}()
+
+	return publisher
+}
+
+var sensorIDPattern = regexp.MustCompile(`[^a-z0-9_]+`)
+
+func sanitizeSensorID(name string) string {
+	id := strings.ToLower(strings.TrimSpace(name))
+	id = sensorIDPattern.ReplaceAllString(id, "_")
+	return strings.Trim(id, "_")
+}
+
+// Publish sends the current state for every sensor in status.All, emitting
+// a HomeAssistant discovery config the first time a given sensor is seen.
+func (m *MQTTPublisher) Publish(status *SystemStatus) {
+	if !m.client.IsConnectionOpen() {
+		return
+	}
+
+	for _, s := range status.All {
+		if s.Value == nil {
+			continue
+		}
+
+		id := sanitizeSensorID(fmt.Sprintf("%s_gpu%d", s.Name, s.GpuIndex))
+		base := "homeassistant/sensor/fps_monitor_" + id
+
+		if !m.announced[id] {
+			m.publishDiscovery(base, id, s)
+			m.announced[id] = true
+		}
+
+		m.client.Publish(base+"/state", 0, true, fmt.Sprintf("%g", *s.Value))
+	}
+}
+
+func (m *MQTTPublisher) publishDiscovery(base, id string, s SensorData) {
+	config := map[string]interface{}{
+		"name":                s.Name,
+		"state_topic":         base + "/state",
+		"unique_id":           id,
+		"unit_of_measurement": s.Unit,
+	}
+	if deviceClass := deviceClassForUnit(s.Unit); deviceClass != "" {
+		config["device_class"] = deviceClass
+	}
+
+	payload, err := json.Marshal(config)
+	if err != nil {
+		return
+	}
+	m.client.Publish(base+"/config", 0, true, payload)
+}
+
+// deviceClassForUnit maps a sensor's unit to a HomeAssistant device_class so
+// the Lovelace UI picks a sensible icon and display format. Units that
+// don't map to a known class are left unset.
+func deviceClassForUnit(unit string) string {
+	switch strings.ToLower(unit) {
+	case "c", "°c", "f", "°f":
+		return "temperature"
+	case "w", "watts":
+		return "power"
+	case "mhz", "hz":
+		return "frequency"
+	default:
+		return ""
+	}
+}
+
+// Close disconnects the MQTT client.
+func (m *MQTTPublisher) Close() {
+	m.client.Disconnect(250)
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestSanitizeSensorID(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"already clean", "gpu_temp_gpu0", "gpu_temp_gpu0"},
+		{"spaces and punctuation", "GPU Temp #1", "gpu_temp_1"},
+		{"leading and trailing junk", "  °C Framerate!!  ", "c_framerate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeSensorID(tt.in); got != tt.want {
+				t.Errorf("sanitizeSensorID(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeviceClassForUnit(t *testing.T) {
+	tests := []struct {
+		unit string
+		want string
+	}{
+		{"C", "temperature"},
+		{"°F", "temperature"},
+		{"W", "power"},
+		{"MHz", "frequency"},
+		{"%", ""},
+		{"RPM", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			if got := deviceClassForUnit(tt.unit); got != tt.want {
+				t.Errorf("deviceClassForUnit(%q) = %q, want %q", tt.unit, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessStats is the per-process enrichment exposed at /process and
+// merged into /cpu and /memory under the "process:<name>" category.
+type ProcessStats struct {
+	Name        string  `json:"name"`
+	Pid         int32   `json:"pid"`
+	CPUPercent  float64 `json:"cpu_percent"`
+	RSSBytes    uint64  `json:"rss_bytes"`
+	VMSBytes    uint64  `json:"vms_bytes"`
+	NumThreads  int32   `json:"num_threads"`
+	ReadBytes   uint64  `json:"read_bytes"`
+	WriteBytes  uint64  `json:"write_bytes"`
+	OpenHandles int32   `json:"open_handles"`
+	Timestamp   int64   `json:"timestamp"`
+}
+
+// gameProcessName is the process to enrich readings for. RTSS doesn't
+// expose the foreground game's process name through the MAHM segment we
+// read, so for now this is set from the --game-process flag rather than
+// detected automatically.
+var gameProcessName string
+
+// enrichProcessStats looks up the configured game process by name and
+// merges its resource usage into status: a ProcessStats blob for /process,
+// plus a CPU and memory SensorData tagged "process:<name>" so /cpu and
+// /memory reflect the game itself alongside host-wide totals.
+func enrichProcessStats(status *SystemStatus, name string) {
+	if name == "" {
+		return
+	}
+
+	procs, err := process.Processes()
+	if err != nil {
+		return
+	}
+
+	for _, p := range procs {
+		pname, err := p.Name()
+		if err != nil || pname != name {
+			continue
+		}
+
+		stats := &ProcessStats{Name: pname, Pid: p.Pid, Timestamp: status.Timestamp}
+		if cpuPct, err := p.CPUPercent(); err == nil {
+			stats.CPUPercent = cpuPct
+		}
+		if mem, err := p.MemoryInfo(); err == nil && mem != nil {
+			stats.RSSBytes = mem.RSS
+			stats.VMSBytes = mem.VMS
+		}
+		if threads, err := p.NumThreads(); err == nil {
+			stats.NumThreads = threads
+		}
+		if io, err := p.IOCounters(); err == nil && io != nil {
+			stats.ReadBytes = io.ReadBytes
+			stats.WriteBytes = io.WriteBytes
+		}
+		if handles, err := p.NumFDs(); err == nil {
+			stats.OpenHandles = handles
+		}
+
+		mergeProcessStats(status, stats)
+		return
+	}
+}
+
+// mergeProcessStats attaches stats to status: the full blob for /process,
+// plus a CPU and memory SensorData tagged "process:<name>" so /cpu and
+// /memory reflect the game itself alongside host-wide totals. Split out
+// from enrichProcessStats so the merge logic can be tested without a real
+// process to look up.
+func mergeProcessStats(status *SystemStatus, stats *ProcessStats) {
+	status.Process = stats
+
+	category := "process:" + stats.Name
+	cpuSensor := SensorData{
+		Name: "process_cpu_percent", Value: floatPtr(float32(stats.CPUPercent)),
+		Unit: "%", Category: category, Timestamp: status.Timestamp,
+	}
+	memSensor := SensorData{
+		Name: "process_rss_bytes", Value: floatPtr(float32(stats.RSSBytes)),
+		Unit: "bytes", Category: category, Timestamp: status.Timestamp,
+	}
+	status.CPU = append(status.CPU, cpuSensor)
+	status.Memory = append(status.Memory, memSensor)
+	status.All = append(status.All, cpuSensor, memSensor)
+}
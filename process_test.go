@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestMergeProcessStats(t *testing.T) {
+	status := &SystemStatus{Timestamp: 42}
+	stats := &ProcessStats{Name: "game.exe", Pid: 123, CPUPercent: 12.5, RSSBytes: 1024}
+
+	mergeProcessStats(status, stats)
+
+	if status.Process != stats {
+		t.Fatalf("status.Process = %v, want %v", status.Process, stats)
+	}
+
+	wantCategory := "process:game.exe"
+	if len(status.CPU) != 1 || status.CPU[0].Category != wantCategory {
+		t.Fatalf("status.CPU = %+v, want one sensor categorized %q", status.CPU, wantCategory)
+	}
+	if len(status.Memory) != 1 || status.Memory[0].Category != wantCategory {
+		t.Fatalf("status.Memory = %+v, want one sensor categorized %q", status.Memory, wantCategory)
+	}
+	if len(status.All) != 2 {
+		t.Fatalf("status.All = %+v, want 2 sensors", status.All)
+	}
+
+	if got := *status.CPU[0].Value; got != 12.5 {
+		t.Errorf("cpu sensor value = %v, want 12.5", got)
+	}
+	if got := *status.Memory[0].Value; got != 1024 {
+		t.Errorf("memory sensor value = %v, want 1024", got)
+	}
+}
+
+func TestEnrichProcessStatsNoGameConfigured(t *testing.T) {
+	status := &SystemStatus{Timestamp: 1}
+	enrichProcessStats(status, "")
+
+	if status.Process != nil || len(status.All) != 0 {
+		t.Fatalf("expected no-op when name is empty, got %+v", status)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+// SensorSource is implemented by each backend capable of populating a
+// SystemStatus snapshot. collectData tries sources in order and keeps the
+// first one that reports data, so MAHM is preferred when MSI Afterburner is
+// running and gopsutil is used as the cross-platform fallback otherwise.
+type SensorSource interface {
+	// Collect populates status with the latest readings and reports
+	// whether it found any data.
+	Collect(status *SystemStatus) bool
+}
+
+func floatPtr(v float32) *float32 {
+	return &v
+}
+
+// addSensor appends a reading to the All slice and to the per-category
+// slice the existing /cpu, /gpu, /memory etc. endpoints serve from. Unlike
+// the old fixed-size arrays, this never truncates a source that reports
+// more than 100 sensors.
+func addSensor(status *SystemStatus, category, name string, value *float32, unit string, gpuIndex uint32) {
+	s := SensorData{
+		Name:      name,
+		Value:     value,
+		Unit:      unit,
+		GpuIndex:  gpuIndex,
+		Category:  category,
+		Timestamp: status.Timestamp,
+	}
+
+	status.All = append(status.All, s)
+
+	switch category {
+	case "fps":
+		status.FPS = append(status.FPS, s)
+	case "gpu":
+		status.GPU = append(status.GPU, s)
+	case "cpu":
+		status.CPU = append(status.CPU, s)
+	case "memory":
+		status.Memory = append(status.Memory, s)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// killExistingProcess mirrors the Windows tasklist/taskkill dance using
+// gopsutil, so a stray fps_tray instance doesn't keep the port bound when
+// the tray app is relaunched on Linux/macOS.
+func killExistingProcess() {
+	currentPID := int32(os.Getpid())
+
+	procs, err := process.Processes()
+	if err != nil {
+		return // Ignore errors, just continue
+	}
+
+	for _, p := range procs {
+		if p.Pid == currentPID {
+			continue
+		}
+		name, err := p.Name()
+		if err != nil || name != "fps_tray" {
+			continue
+		}
+		fmt.Printf("Killing existing fps_tray process (PID: %d)\n", p.Pid)
+		p.Kill() // Ignore errors
+	}
+
+	// Give a moment for the process to terminate
+	time.Sleep(100 * time.Millisecond)
+}
+
+// defaultSensorSources uses gopsutil alone since MAHM's shared memory
+// protocol is Windows-only.
+func defaultSensorSources() []SensorSource {
+	return []SensorSource{&GopsutilSource{}}
+}
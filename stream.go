@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// StreamEvent is the payload pushed to /stream/ws and /stream/sse
+// subscribers: every sensor reading from the most recent tick, optionally
+// filtered down to the categories the client asked for.
+type StreamEvent struct {
+	Timestamp int64        `json:"timestamp"`
+	Sensors   []SensorData `json:"sensors"`
+}
+
+// subscriber is one connected streaming client. ch is buffered so a slow
+// consumer doesn't block the collector; publish drops updates for a
+// subscriber whose buffer is already full instead of waiting on it.
+type subscriber struct {
+	ch chan *SystemStatus
+}
+
+// broadcaster fans each collectData tick out to every connected /stream/ws
+// and /stream/sse client.
+type broadcaster struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{subscribers: make(map[*subscriber]struct{})}
+}
+
+func (b *broadcaster) subscribe() *subscriber {
+	sub := &subscriber{ch: make(chan *SystemStatus, 8)}
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+func (b *broadcaster) unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	delete(b.subscribers, sub)
+	b.mu.Unlock()
+}
+
+func (b *broadcaster) publish(status *SystemStatus) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- status:
+		default:
+			// Slow consumer: drop this tick rather than block the collector
+			// or every other subscriber.
+		}
+	}
+}
+
+func parseCategories(r *http.Request) map[string]bool {
+	raw := r.URL.Query().Get("categories")
+	if raw == "" {
+		return nil
+	}
+	categories := make(map[string]bool)
+	for _, c := range strings.Split(raw, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			categories[c] = true
+		}
+	}
+	return categories
+}
+
+func parseRate(r *http.Request) time.Duration {
+	raw := r.URL.Query().Get("rate")
+	if raw == "" {
+		return 0
+	}
+	rate, err := time.ParseDuration(raw)
+	if err != nil || rate < 0 {
+		return 0
+	}
+	return rate
+}
+
+func buildStreamEvent(status *SystemStatus, categories map[string]bool) StreamEvent {
+	event := StreamEvent{Timestamp: status.Timestamp}
+	for _, s := range status.All {
+		if len(categories) > 0 && !categories[s.Category] {
+			continue
+		}
+		event.Sensors = append(event.Sensors, s)
+	}
+	return event
+}
+
+var streamUpgrader = websocket.Upgrader{
+	// Dashboards and HomeAssistant cards connect from arbitrary origins.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// handleStreamWS pushes a StreamEvent over a WebSocket connection every
+// time collectData ticks, instead of forcing the client to poll /fps,
+// /gpu etc.
+func (app *FPSApp) handleStreamWS(w http.ResponseWriter, r *http.Request) {
+	categories := parseCategories(r)
+	rate := parseRate(r)
+
+	conn, err := streamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sub := app.broadcaster.subscribe()
+	defer app.broadcaster.unsubscribe(sub)
+
+	var lastSent time.Time
+	for status := range sub.ch {
+		if rate > 0 && time.Since(lastSent) < rate {
+			continue
+		}
+		lastSent = time.Now()
+		if err := conn.WriteJSON(buildStreamEvent(status, categories)); err != nil {
+			return
+		}
+	}
+}
+
+// handleStreamSSE is the text/event-stream equivalent of handleStreamWS,
+// for clients (browser EventSource, curl) that don't want a WebSocket.
+func (app *FPSApp) handleStreamSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	categories := parseCategories(r)
+	rate := parseRate(r)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	sub := app.broadcaster.subscribe()
+	defer app.broadcaster.unsubscribe(sub)
+
+	var lastSent time.Time
+	for {
+		select {
+		case status, ok := <-sub.ch:
+			if !ok {
+				return
+			}
+			if rate > 0 && time.Since(lastSent) < rate {
+				continue
+			}
+			lastSent = time.Now()
+			payload, err := json.Marshal(buildStreamEvent(status, categories))
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", payload)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
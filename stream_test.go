@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseCategories(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  map[string]bool
+	}{
+		{"absent", "", nil},
+		{"single", "categories=fps", map[string]bool{"fps": true}},
+		{"multiple", "categories=fps,gpu", map[string]bool{"fps": true, "gpu": true}},
+		{"whitespace and empties", "categories=fps, ,gpu,", map[string]bool{"fps": true, "gpu": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/stream/sse", nil)
+			r.URL.RawQuery = tt.query
+			got := parseCategories(r)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseCategories(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for k := range tt.want {
+				if !got[k] {
+					t.Errorf("parseCategories(%q) missing %q", tt.query, k)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  time.Duration
+	}{
+		{"absent", "", 0},
+		{"valid", "rate=250ms", 250 * time.Millisecond},
+		{"invalid", "rate=not-a-duration", 0},
+		{"negative", "rate=-1s", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/stream/sse", nil)
+			r.URL.RawQuery = tt.query
+			if got := parseRate(r); got != tt.want {
+				t.Errorf("parseRate(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildStreamEventFiltersCategories(t *testing.T) {
+	status := &SystemStatus{
+		Timestamp: 7,
+		All: []SensorData{
+			{Name: "Framerate", Category: "fps"},
+			{Name: "GPU Temp", Category: "gpu"},
+			{Name: "CPU Load", Category: "cpu"},
+		},
+	}
+
+	event := buildStreamEvent(status, map[string]bool{"gpu": true})
+
+	if event.Timestamp != 7 {
+		t.Errorf("Timestamp = %d, want 7", event.Timestamp)
+	}
+	if len(event.Sensors) != 1 || event.Sensors[0].Category != "gpu" {
+		t.Fatalf("Sensors = %+v, want only the gpu sensor", event.Sensors)
+	}
+}
+
+func TestBuildStreamEventNoFilterReturnsAll(t *testing.T) {
+	status := &SystemStatus{
+		All: []SensorData{
+			{Name: "Framerate", Category: "fps"},
+			{Name: "GPU Temp", Category: "gpu"},
+		},
+	}
+
+	event := buildStreamEvent(status, nil)
+
+	if len(event.Sensors) != 2 {
+		t.Fatalf("Sensors = %+v, want all 2 sensors", event.Sensors)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// trayMenuItem stubs github.com/getlantern/systray's MenuItem on platforms
+// where the tray isn't available. getlantern/systray needs cgo plus
+// GTK/ayatana-appindicator dev headers on Linux, which a headless or
+// minimal gaming rig (e.g. a Steam Deck) won't have installed, so the
+// server runs headless there instead of failing to build.
+type trayMenuItem struct {
+	ClickedCh chan struct{}
+}
+
+// trayRun runs onReady immediately and blocks until SIGINT/SIGTERM, then
+// runs onExit — mirroring systray.Run's onReady-then-block-until-quit
+// behavior without requiring an actual tray.
+func trayRun(onReady, onExit func()) {
+	fmt.Println("system tray is not available on this platform; running headless (Ctrl+C to quit)")
+	onReady()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	onExit()
+}
+
+func traySetIcon(icon []byte)       {}
+func traySetTitle(title string)     {}
+func traySetTooltip(tooltip string) {}
+func trayAddSeparator()             {}
+
+func trayAddMenuItem(title, tooltip string) *trayMenuItem {
+	// No clicks ever arrive on this channel; quitting headless happens via
+	// the signal handled in trayRun instead of a menu item.
+	return &trayMenuItem{ClickedCh: make(chan struct{})}
+}
+
+func trayQuit() {}
@@ -0,0 +1,39 @@
+//go:build windows
+// +build windows
+
+package main
+
+import "github.com/getlantern/systray"
+
+// trayMenuItem aliases systray's type so callers can read ClickedCh without
+// importing systray directly; the non-Windows build provides its own
+// trayMenuItem with the same field.
+type trayMenuItem = systray.MenuItem
+
+func trayRun(onReady, onExit func()) {
+	systray.Run(onReady, onExit)
+}
+
+func traySetIcon(icon []byte) {
+	systray.SetIcon(icon)
+}
+
+func traySetTitle(title string) {
+	systray.SetTitle(title)
+}
+
+func traySetTooltip(tooltip string) {
+	systray.SetTooltip(tooltip)
+}
+
+func trayAddSeparator() {
+	systray.AddSeparator()
+}
+
+func trayAddMenuItem(title, tooltip string) *trayMenuItem {
+	return systray.AddMenuItem(title, tooltip)
+}
+
+func trayQuit() {
+	systray.Quit()
+}